@@ -0,0 +1,627 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/i-home/qml"
+)
+
+// bundleSpec is one -bundle name=dir group, collecting every subdir given
+// for that name in the order the flags appeared.
+type bundleSpec struct {
+	Name string
+	Dirs []string
+}
+
+// bundleFlags implements flag.Value for repeated -bundle name=dir flags.
+type bundleFlags struct {
+	order []string
+	dirs  map[string][]string
+}
+
+func (b *bundleFlags) String() string {
+	return ""
+}
+
+func (b *bundleFlags) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf(`-bundle must be in the form name=dir, got %q`, value)
+	}
+	name, dir := parts[0], parts[1]
+	if b.dirs == nil {
+		b.dirs = make(map[string][]string)
+	}
+	if _, seen := b.dirs[name]; !seen {
+		b.order = append(b.order, name)
+	}
+	b.dirs[name] = append(b.dirs[name], dir)
+	return nil
+}
+
+func (b *bundleFlags) specs() []bundleSpec {
+	specs := make([]bundleSpec, len(b.order))
+	for i, name := range b.order {
+		specs[i] = bundleSpec{Name: name, Dirs: b.dirs[name]}
+	}
+	return specs
+}
+
+var bundleList bundleFlags
+
+func init() {
+	flag.Var(&bundleList, "bundle", "name=dir; packs dir into a named bundle exposed as LoadNameResources (repeatable)")
+}
+
+var externalBundles = flag.String("external", "", "comma-separated bundle names to write as external sidecar files instead of embedding")
+
+// exportName turns a bundle name into an exported Go identifier fragment,
+// e.g. "ui" -> "Ui", "i18n" -> "I18n". Runs of characters that can't appear
+// in a Go identifier (as well as "_") are treated as word separators and
+// dropped, with the following letter upper-cased, so names that are
+// perfectly normal as directory/-bundle labels but not as Go identifiers
+// (e.g. "ui-dark", "i18n.en") still produce valid, readable output:
+// "ui-dark" -> "UiDark", "i18n.en" -> "I18nEn".
+func exportName(name string) string {
+	var out []rune
+	upperNext := true
+	for _, r := range name {
+		if r == '_' || (!unicode.IsLetter(r) && !unicode.IsDigit(r)) {
+			upperNext = true
+			continue
+		}
+		if len(out) == 0 && unicode.IsDigit(r) {
+			// A leading digit would make the identifier invalid; prefix it.
+			out = append(out, '_')
+		}
+		if upperNext {
+			r = unicode.ToUpper(r)
+			upperNext = false
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
+
+// runBundlesFormat packs each -bundle group independently, prefixing every
+// entry with the bundle name so it resolves under "qrc:///<name>/...", and
+// emits one lazily-invoked LoadNameResources func per bundle instead of an
+// init() that loads everything unconditionally.
+func runBundlesFormat(specs []bundleSpec) error {
+	if *format != "go" {
+		return fmt.Errorf("-bundle is only supported with -format=go")
+	}
+
+	external := make(map[string]bool)
+	for _, name := range strings.Split(*externalBundles, ",") {
+		if name != "" {
+			external[name] = true
+		}
+	}
+
+	output := *outputPath
+	if output == "" {
+		output = "qrc.go"
+	}
+
+	cacheDir := filepath.Dir(output)
+
+	var blocks []bundleTemplateData
+	var hasEmbedded bool
+	for _, spec := range specs {
+		cacheName := spec.Name + ".qrc.cache"
+		signature := cacheSignature(*compress, *compressThreshold)
+		oldCache := loadResourceCache(cacheDir, cacheName, signature)
+		resdata, newCache, err := packSubdirsCached(spec.Name, spec.Dirs, oldCache)
+		if err != nil {
+			return fmt.Errorf("bundle %q: %v", spec.Name, err)
+		}
+
+		id := exportName(spec.Name)
+		block := bundleTemplateData{
+			Name:           spec.Name,
+			SubDirs:        spec.Dirs,
+			FuncName:       "Load" + id + "Resources",
+			DataVar:        lowerFirst(id) + "ResourcesData",
+			RepackVar:      "qrcRepack" + id + "Data",
+			RepackFuncName: "qrcRepack" + id + "Resources",
+			WatchFuncName:  "qrcWatch" + id + "Resources",
+			WatchOnceVar:   "qrcWatch" + id + "Once",
+		}
+		if external[spec.Name] {
+			sidecar := spec.Name + ".qrcdata"
+			if err := ioutil.WriteFile(sidecar, resdata, 0644); err != nil {
+				return err
+			}
+			block.External = true
+			block.SidecarPath = sidecar
+		} else {
+			block.ResourcesData = resdata
+			hasEmbedded = true
+		}
+		blocks = append(blocks, block)
+
+		if err := saveResourceCache(cacheDir, cacheName, newCache); err != nil {
+			return err
+		}
+	}
+
+	pkgName := *packageName
+	if pkgname := os.Getenv("GOPACKAGE"); pkgname != "" {
+		pkgName = pkgname
+	}
+
+	var buf bytes.Buffer
+	if err := bundlesTmpl.Execute(&buf, bundlesTemplateData{
+		PackageName:       pkgName,
+		Bundles:           blocks,
+		HasEmbedded:       hasEmbedded,
+		Include:           *include,
+		Exclude:           *exclude,
+		Ext:               *ext,
+		Compress:          *compress,
+		CompressThreshold: *compressThreshold,
+	}); err != nil {
+		return err
+	}
+
+	if old, err := ioutil.ReadFile(output); err != nil || !bytes.Equal(old, buf.Bytes()) {
+		if err := ioutil.WriteFile(output, buf.Bytes(), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// packSubdirsCached walks dirs the same way the single-bundle generator does
+// (honoring -include/-exclude/-ext, .qrcignore and -compress) and packs the
+// result with every entry prefixed by name, so it resolves at runtime under
+// the "qrc:///<name>/..." URL space. oldCache is this bundle's own qrc.cache
+// sidecar from the previous run; a freshly populated cache is returned for
+// the caller to save back.
+func packSubdirsCached(name string, dirs []string, oldCache resourceCache) ([]byte, resourceCache, error) {
+	algo, err := resolveCompression(*compress)
+	if err != nil {
+		return nil, resourceCache{}, err
+	}
+
+	filter := newResourceFilter(*include, *exclude, *ext)
+	var entries []resourceEntry
+	err = walkResourceFiles(dirs, filter, func(slashPath, fsPath string, info os.FileInfo) error {
+		entries = append(entries, resourceEntry{
+			SlashPath: slashPath,
+			FSPath:    fsPath,
+			Size:      info.Size(),
+			ModTime:   info.ModTime().UnixNano(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, resourceCache{}, err
+	}
+
+	sort.Sort(byPath(entries))
+
+	var rp qml.ResourcesPacker
+	rp.SetCompression(algo, *compressThreshold)
+	newCache := resourceCache{Signature: oldCache.Signature, Entries: make(map[string]cacheEntry, len(entries))}
+	for _, e := range entries {
+		if prev, ok := oldCache.unchanged(e.SlashPath, e.Size, e.ModTime); ok {
+			rp.AddPacked(name+"/"+e.SlashPath, prev.Compress, prev.OrigSize, prev.Packed)
+			newCache.Entries[e.SlashPath] = prev
+			continue
+		}
+
+		data, err := ioutil.ReadFile(e.FSPath)
+		if err != nil {
+			return nil, resourceCache{}, err
+		}
+		rp.Add(name+"/"+e.SlashPath, data)
+		packedCompress, origSize, packed := rp.LastPacked()
+		newCache.Entries[e.SlashPath] = cacheEntry{
+			Size:     e.Size,
+			ModTime:  e.ModTime,
+			Hash:     hashContent(data),
+			Compress: packedCompress,
+			OrigSize: origSize,
+			Packed:   packed,
+		}
+	}
+	return rp.Pack().Bytes(), newCache, nil
+}
+
+type bundlesTemplateData struct {
+	PackageName string
+	Bundles     []bundleTemplateData
+	// HasEmbedded is true when at least one bundle is embedded rather than
+	// external, so the generated file needs the shared QRC_REPACK/QRC_WATCH
+	// helpers (and their fsnotify/path/filepath/etc. imports).
+	HasEmbedded       bool
+	Include           string
+	Exclude           string
+	Ext               string
+	Compress          string
+	CompressThreshold int
+}
+
+type bundleTemplateData struct {
+	Name        string
+	SubDirs     []string
+	FuncName    string
+	DataVar     string
+	External    bool
+	SidecarPath string
+
+	ResourcesData []byte
+
+	// RepackVar/RepackFuncName/WatchFuncName/WatchOnceVar are only used for
+	// embedded bundles, to support QRC_REPACK/QRC_WATCH the same way
+	// qrc.go's single-bundle output does.
+	RepackVar      string
+	RepackFuncName string
+	WatchFuncName  string
+	WatchOnceVar   string
+}
+
+// lowerFirst lower-cases the first rune of an exportName result, for the
+// unexported package-level vars that back each bundle's embedded data.
+func lowerFirst(s string) string {
+	r := []rune(s)
+	if len(r) == 0 {
+		return s
+	}
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
+var bundlesTmpl = buildTemplate("qrc-bundles.go", `package {{.PackageName}}
+
+// This file is automatically generated by github.com/i-home/qml/cmd/genqrc
+
+import (
+{{if .HasEmbedded}}	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+{{end}}	"github.com/i-home/qml"
+)
+{{if .HasEmbedded}}
+// qrcWatchDebounce coalesces bursts of fsnotify events into a single
+// repack per bundle, so a large tree doesn't get repacked once per raw
+// event.
+const qrcWatchDebounce = 200 * time.Millisecond
+
+// qrcRepackInclude, qrcRepackExclude and qrcRepackExt mirror the -include,
+// -exclude and -ext flags genqrc was run with, so that QRC_REPACK=1 filters
+// files the same way every bundle's embedded data was filtered at
+// generation time.
+var (
+	qrcRepackInclude = {{printf "%q" .Include}}
+	qrcRepackExclude = {{printf "%q" .Exclude}}
+	qrcRepackExt     = {{printf "%q" .Ext}}
+)
+
+var qrcRepackDefaultExt = []string{` + defaultExtsLiteral + `}
+
+const qrcRepackQmldirBasename = "qmldir"
+
+func qrcRepackSplit(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+func qrcRepackMatchAny(patterns []string, slashPath string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, slashPath); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(slashPath)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// qrcRepackRule mirrors filters.go's ignoreRule: baseDir is the
+// slash-normalized directory the .qrcignore line came from, which patterns
+// containing a "/" are matched relative to.
+type qrcRepackRule struct {
+	baseDir string
+	pattern string
+	negate  bool
+}
+
+func qrcRepackLoadIgnore(dir string) []qrcRepackRule {
+	f, err := os.Open(filepath.Join(dir, ".qrcignore"))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	baseDir := filepath.ToSlash(dir)
+	var rules []qrcRepackRule
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := qrcRepackRule{baseDir: baseDir}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		rule.pattern = strings.TrimSuffix(line, "/")
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// qrcRepackRuleMatches mirrors filters.go's ruleMatches.
+func qrcRepackRuleMatches(rule qrcRepackRule, slashPath string) bool {
+	if !strings.Contains(rule.pattern, "/") {
+		matched, _ := path.Match(rule.pattern, path.Base(slashPath))
+		return matched
+	}
+	rel := slashPath
+	if rule.baseDir != "" && rule.baseDir != "." {
+		rel = strings.TrimPrefix(slashPath, rule.baseDir+"/")
+	}
+	matched, _ := path.Match(rule.pattern, rel)
+	return matched
+}
+
+// qrcRepackAllowed replicates the include/exclude/ext and .qrcignore
+// filtering genqrc applied when this bundle was packed. The .qrcignore
+// rules accumulate with last-match-wins semantics, same as build-time
+// ignoredByQRCIgnore, so a child directory's negated rule can override one
+// inherited from a parent .qrcignore.
+func qrcRepackAllowed(ignoreCache map[string][]qrcRepackRule, slashPath string) bool {
+	exts := qrcRepackDefaultExt
+	if qrcRepackExt != "" {
+		exts = qrcRepackSplit(qrcRepackExt)
+	}
+	base := path.Base(slashPath)
+	if base != qrcRepackQmldirBasename {
+		ok := false
+		ext := path.Ext(slashPath)
+		for _, e := range exts {
+			if !strings.HasPrefix(e, ".") {
+				e = "." + e
+			}
+			if e == ext {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+
+	includes := qrcRepackSplit(qrcRepackInclude)
+	if len(includes) > 0 && !qrcRepackMatchAny(includes, slashPath) {
+		return false
+	}
+	if qrcRepackMatchAny(qrcRepackSplit(qrcRepackExclude), slashPath) {
+		return false
+	}
+
+	ignored := false
+	for _, rule := range qrcRepackRulesFor(ignoreCache, path.Dir(slashPath)) {
+		if qrcRepackRuleMatches(rule, slashPath) {
+			ignored = !rule.negate
+		}
+	}
+	return !ignored
+}
+
+// qrcRepackRulesFor returns the cumulative .qrcignore rules that apply to
+// dir: its own rules plus everything inherited from parent directories.
+func qrcRepackRulesFor(ignoreCache map[string][]qrcRepackRule, dir string) []qrcRepackRule {
+	if rules, ok := ignoreCache[dir]; ok {
+		return rules
+	}
+	rules := qrcRepackLoadIgnore(filepath.FromSlash(dir))
+	if parent := path.Dir(dir); parent != dir {
+		rules = append(qrcRepackRulesFor(ignoreCache, parent), rules...)
+	}
+	ignoreCache[dir] = rules
+	return rules
+}
+{{end}}
+{{range .Bundles}}
+{{if .External}}
+// {{.FuncName}} loads the "{{.Name}}" bundle from its external sidecar file,
+// registering its contents under the "qrc:///{{.Name}}/..." URL space.
+// QRC_REPACK/QRC_WATCH don't apply to external bundles: re-run genqrc to
+// refresh the sidecar file on disk.
+func {{.FuncName}}() error {
+	return qml.LoadResourcesFile({{printf "%q" .Name}}, {{printf "%q" .SidecarPath}})
+}
+{{else}}
+var {{.DataVar}} = {{printf "%q" .ResourcesData}}
+var {{.RepackVar}} []byte
+var {{.WatchOnceVar}} sync.Once
+
+// {{.FuncName}} loads the "{{.Name}}" bundle, registering its contents
+// under the "qrc:///{{.Name}}/..." URL space. Unlike the single-bundle
+// qrc.go output, bundles are not loaded automatically by an init() func;
+// call {{.FuncName}} explicitly when the application needs them, as many
+// times as needed (e.g. every time a screen using it is re-entered).
+// Setting QRC_REPACK=1 or QRC_WATCH=1 repacks this bundle's SubDirs from
+// disk instead of using the embedded {{.DataVar}} snapshot, same as
+// qrc.go's single-bundle output; QRC_WATCH additionally reloads it once
+// changes settle, debounced the same way, via a single watch goroutine
+// shared across every call to {{.FuncName}}.
+func {{.FuncName}}() error {
+	if os.Getenv("QRC_REPACK") != "1" && os.Getenv("QRC_WATCH") != "1" {
+		r, err := qml.ParseResourcesString({{.DataVar}})
+		if err != nil {
+			return err
+		}
+		qml.LoadResources({{printf "%q" .Name}}, r)
+		return nil
+	}
+
+	if err := {{.RepackFuncName}}(); err != nil {
+		return err
+	}
+	r, err := qml.ParseResources({{.RepackVar}})
+	if err != nil {
+		return err
+	}
+	qml.LoadResources({{printf "%q" .Name}}, r)
+
+	if os.Getenv("QRC_WATCH") == "1" {
+		{{.WatchOnceVar}}.Do(func() { go {{.WatchFuncName}}() })
+	}
+	return nil
+}
+
+// {{.RepackFuncName}} repacks the "{{.Name}}" bundle's SubDirs from disk
+// into {{.RepackVar}}, applying the same filters and compression as the
+// embedded snapshot.
+func {{.RepackFuncName}}() error {
+	subdirs := {{printf "%#v" .SubDirs}}
+	var rp qml.ResourcesPacker
+	switch {{printf "%q" $.Compress}} {
+	case "zlib":
+		rp.SetCompression(qml.CompressZlib, {{$.CompressThreshold}})
+	case "zstd":
+		rp.SetCompression(qml.CompressZstd, {{$.CompressThreshold}})
+	default:
+		rp.SetCompression(qml.CompressNone, {{$.CompressThreshold}})
+	}
+	ignoreCache := make(map[string][]qrcRepackRule)
+	type qrcRepackEntry struct {
+		SlashPath string
+		Data      []byte
+	}
+	var entries []qrcRepackEntry
+	for _, subdir := range subdirs {
+		err := filepath.Walk(subdir, func(fpath string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			slashPath := filepath.ToSlash(fpath)
+			if !qrcRepackAllowed(ignoreCache, slashPath) {
+				return nil
+			}
+			data, err := ioutil.ReadFile(fpath)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, qrcRepackEntry{slashPath, data})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].SlashPath < entries[j].SlashPath })
+	for _, e := range entries {
+		rp.Add({{printf "%q" .Name}}+"/"+e.SlashPath, e.Data)
+	}
+	{{.RepackVar}} = rp.Pack().Bytes()
+	return nil
+}
+
+// {{.WatchFuncName}} watches the "{{.Name}}" bundle's SubDirs for changes
+// and, once changes settle for a short debounce window, repacks and
+// reloads it so a running QML app picks up edits without a restart.
+// Debouncing coalesces a burst of events (e.g. an editor's
+// rename-and-replace save) into a single repack instead of one per raw
+// fsnotify event.
+func {{.WatchFuncName}}() {
+	subdirs := {{printf "%#v" .SubDirs}}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "qrc watch ({{.Name}}): cannot start fsnotify watcher:", err)
+		return
+	}
+	defer watcher.Close()
+
+	addWatches := func() {
+		for _, subdir := range subdirs {
+			filepath.Walk(subdir, func(path string, info os.FileInfo, err error) error {
+				if err == nil && info.IsDir() {
+					watcher.Add(path)
+				}
+				return nil
+			})
+		}
+	}
+	addWatches()
+
+	debounce := time.NewTimer(qrcWatchDebounce)
+	if !debounce.Stop() {
+		<-debounce.C
+	}
+	pending := false
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Create|fsnotify.Write|fsnotify.Remove|fsnotify.Rename) == 0 {
+				continue
+			}
+			if event.Op&fsnotify.Create != 0 {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					watcher.Add(event.Name)
+				}
+			}
+			if pending {
+				if !debounce.Stop() {
+					<-debounce.C
+				}
+			}
+			pending = true
+			debounce.Reset(qrcWatchDebounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			fmt.Fprintln(os.Stderr, "qrc watch ({{.Name}}): fsnotify error:", err)
+		case <-debounce.C:
+			pending = false
+			if err := {{.RepackFuncName}}(); err != nil {
+				fmt.Fprintln(os.Stderr, "qrc watch ({{.Name}}): repack failed:", err)
+				continue
+			}
+			r, err := qml.ParseResources({{.RepackVar}})
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "qrc watch ({{.Name}}): cannot parse repacked resources:", err)
+				continue
+			}
+			qml.LoadResources({{printf "%q" .Name}}, r)
+		}
+	}
+}
+{{end}}
+{{end}}
+`)
@@ -0,0 +1,21 @@
+package main
+
+import "testing"
+
+func TestExportName(t *testing.T) {
+	cases := map[string]string{
+		"ui":         "Ui",
+		"i18n":       "I18n",
+		"ui-dark":    "UiDark",
+		"i18n.en":    "I18nEn",
+		"my_bundle":  "MyBundle",
+		"2x-assets":  "_2xAssets",
+		"":           "",
+		"--leading-": "Leading",
+	}
+	for in, want := range cases {
+		if got := exportName(in); got != want {
+			t.Errorf("exportName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
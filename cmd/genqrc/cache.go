@@ -0,0 +1,92 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/i-home/qml"
+)
+
+// cacheFile is the sidecar written next to the generated qrc.go recording
+// enough per-file state to skip re-reading and re-compressing files that
+// haven't changed since the last genqrc run. Each -bundle uses its own
+// "<name>.qrc.cache" sidecar instead, so bundles packed into the same
+// output don't clobber each other's cache.
+const cacheFile = "qrc.cache"
+
+// cacheEntry records the state of a single packed file as of the last
+// successful genqrc run, including its already-packed (possibly
+// compressed) bytes, so an unchanged file can be re-added to a
+// ResourcesPacker via AddPacked without reading or recompressing it.
+type cacheEntry struct {
+	Size     int64           `json:"size"`
+	ModTime  int64           `json:"mod_time"`
+	Hash     string          `json:"hash"`
+	Compress qml.Compression `json:"compress"`
+	OrigSize int64           `json:"orig_size"`
+	Packed   []byte          `json:"packed"`
+}
+
+// resourceCache is the full qrc.cache sidecar. Signature fingerprints the
+// settings (compression codec and threshold) that produced Entries' Packed
+// bytes; the cache is discarded wholesale when it doesn't match the current
+// run's settings, since Packed bytes compressed under the old settings
+// can't be reused under new ones.
+type resourceCache struct {
+	Signature string                `json:"signature"`
+	Entries   map[string]cacheEntry `json:"entries"`
+}
+
+// cacheSignature fingerprints the packer settings a cache was built under.
+func cacheSignature(compress string, threshold int) string {
+	return fmt.Sprintf("%s:%d", compress, threshold)
+}
+
+// loadResourceCache reads the name sidecar out of dir, returning an empty
+// cache if it doesn't exist, can't be parsed (e.g. from an older genqrc
+// version) or was built under different compress/compressThreshold
+// settings than signature.
+func loadResourceCache(dir, name, signature string) resourceCache {
+	empty := resourceCache{Signature: signature, Entries: map[string]cacheEntry{}}
+
+	data, err := ioutil.ReadFile(dir + string(os.PathSeparator) + name)
+	if err != nil {
+		return empty
+	}
+	var cache resourceCache
+	if err := json.Unmarshal(data, &cache); err != nil || cache.Entries == nil {
+		return empty
+	}
+	if cache.Signature != signature {
+		return empty
+	}
+	return cache
+}
+
+func saveResourceCache(dir, name string, cache resourceCache) error {
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(dir+string(os.PathSeparator)+name, data, 0644)
+}
+
+func hashContent(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// unchanged reports whether slashPath's cached entry still matches size and
+// modTime, meaning it can be reused as-is without re-reading or
+// re-compressing the file.
+func (c resourceCache) unchanged(slashPath string, size, modTime int64) (cacheEntry, bool) {
+	prev, ok := c.Entries[slashPath]
+	if !ok || prev.Size != size || prev.ModTime != modTime {
+		return cacheEntry{}, false
+	}
+	return prev, true
+}
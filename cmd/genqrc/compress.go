@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/i-home/qml"
+)
+
+// resolveCompression maps the -compress flag value to a qml.Compression
+// codec. qml.ResourcesPacker applies it per entry, skipping files under
+// -compress-threshold and formats that are already compressed (png, jpg,
+// mp3, ogg, woff2, ...).
+func resolveCompression(name string) (qml.Compression, error) {
+	switch name {
+	case "none":
+		return qml.CompressNone, nil
+	case "zlib":
+		return qml.CompressZlib, nil
+	case "zstd":
+		return qml.CompressZstd, nil
+	default:
+		return qml.CompressNone, fmt.Errorf("unknown -compress %q: must be \"none\", \"zlib\" or \"zstd\"", name)
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// defaultConfigFile is auto-discovered in the working directory when genqrc
+// is run with no subdirectory arguments and no -bundle flags.
+const defaultConfigFile = "qrc.yaml"
+
+var configPath = flag.String("config", "", "path to a qrc.yaml manifest (default: auto-discover ./qrc.yaml)")
+
+// config mirrors the flags genqrc accepts, so a qrc.yaml manifest can
+// encode a whole invocation declaratively instead of on the command line.
+type config struct {
+	Package           string         `yaml:"package"`
+	Output            string         `yaml:"output"`
+	Subdirs           []configSubdir `yaml:"subdirs"`
+	Prefix            string         `yaml:"prefix"`
+	Include           string         `yaml:"include"`
+	Exclude           string         `yaml:"exclude"`
+	Ext               string         `yaml:"ext"`
+	Compress          string         `yaml:"compress"`
+	CompressThreshold int            `yaml:"compress_threshold"`
+	Bundles           []configBundle `yaml:"bundles"`
+}
+
+// configSubdir is one entry of config.Subdirs. Written as a plain string in
+// qrc.yaml, it packs Dir under the manifest-wide Prefix, same as before;
+// written as "{dir: ..., prefix: ...}", it instead gets its own <qresource
+// prefix="..."> block in the generated .qrc XML (-format=qrc only), mirroring
+// how a real .qrc file can hold more than one <qresource> section. -format=go
+// has no such concept and packs every subdir's files flat regardless.
+type configSubdir struct {
+	Dir    string `yaml:"dir"`
+	Prefix string `yaml:"prefix"`
+}
+
+func (s *configSubdir) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var dir string
+	if err := unmarshal(&dir); err == nil {
+		s.Dir = dir
+		return nil
+	}
+	type plain configSubdir
+	var p plain
+	if err := unmarshal(&p); err != nil {
+		return err
+	}
+	*s = configSubdir(p)
+	return nil
+}
+
+type configBundle struct {
+	Name     string   `yaml:"name"`
+	Dirs     []string `yaml:"dirs"`
+	External bool     `yaml:"external"`
+}
+
+func loadConfig(path string) (*config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %v", path, err)
+	}
+	return &cfg, nil
+}
+
+// explicitFlags returns the set of flag names the user passed on the
+// command line, as opposed to ones just carrying their zero-value default.
+// -config can be combined with other explicit flags (e.g.
+// "genqrc -config qrc.yaml -compress=zstd"), so applyConfig needs this to
+// know which fields it must not overwrite.
+func explicitFlags() map[string]bool {
+	explicit := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+	return explicit
+}
+
+// applyConfig overlays non-zero fields from cfg onto the flag values that
+// would otherwise come from the command line, except for flags the user
+// passed explicitly: those always win over the config file, regardless of
+// whether -config was auto-discovered or given explicitly alongside them.
+func applyConfig(cfg *config) {
+	applyConfigExplicit(cfg, explicitFlags())
+}
+
+// applyConfigExplicit is applyConfig's logic with the explicit-flag set
+// passed in directly, so tests can exercise it without going through real
+// command-line parsing of the package-level flags.
+func applyConfigExplicit(cfg *config, explicit map[string]bool) {
+	if cfg.Package != "" && !explicit["package"] {
+		*packageName = cfg.Package
+	}
+	if cfg.Output != "" && !explicit["o"] {
+		*outputPath = cfg.Output
+	}
+	if cfg.Prefix != "" && !explicit["prefix"] {
+		*prefix = cfg.Prefix
+	}
+	if cfg.Include != "" && !explicit["include"] {
+		*include = cfg.Include
+	}
+	if cfg.Exclude != "" && !explicit["exclude"] {
+		*exclude = cfg.Exclude
+	}
+	if cfg.Ext != "" && !explicit["ext"] {
+		*ext = cfg.Ext
+	}
+	if cfg.Compress != "" && !explicit["compress"] {
+		*compress = cfg.Compress
+	}
+	if cfg.CompressThreshold != 0 && !explicit["compress-threshold"] {
+		*compressThreshold = cfg.CompressThreshold
+	}
+}
+
+// runConfig runs genqrc as described by cfg, dispatching to the bundle
+// generator when cfg declares bundles and to the normal single-manifest
+// generator otherwise.
+func runConfig(cfg *config) error {
+	applyConfig(cfg)
+
+	if len(cfg.Bundles) > 0 {
+		var specs []bundleSpec
+		for _, b := range cfg.Bundles {
+			specs = append(specs, bundleSpec{Name: b.Name, Dirs: b.Dirs})
+			if b.External {
+				if *externalBundles == "" {
+					*externalBundles = b.Name
+				} else {
+					*externalBundles += "," + b.Name
+				}
+			}
+		}
+		return runBundlesFormat(specs)
+	}
+
+	if len(cfg.Subdirs) == 0 {
+		return fmt.Errorf("%s: must declare at least one subdir or bundle", defaultConfigFile)
+	}
+
+	switch *format {
+	case "go":
+		dirs := make([]string, len(cfg.Subdirs))
+		for i, s := range cfg.Subdirs {
+			dirs[i] = s.Dir
+		}
+		return runGoFormat(dirs)
+	case "qrc":
+		return runQRCFormatGrouped(cfg.Subdirs)
+	default:
+		return fmt.Errorf("unknown -format %q: must be \"go\" or \"qrc\"", *format)
+	}
+}
+
+// resolveConfigPath returns the manifest to load, if any: -config when
+// given, otherwise ./qrc.yaml when it exists and genqrc was run with no
+// subdirectory arguments or -bundle flags.
+func resolveConfigPath() string {
+	if *configPath != "" {
+		return *configPath
+	}
+	if len(flag.Args()) > 0 || len(bundleList.specs()) > 0 {
+		return ""
+	}
+	if _, err := os.Stat(defaultConfigFile); err == nil {
+		return defaultConfigFile
+	}
+	return ""
+}
@@ -0,0 +1,38 @@
+package main
+
+import "testing"
+
+func TestApplyConfigExplicit(t *testing.T) {
+	saved := *compress
+	defer func() { *compress = saved }()
+
+	// An explicitly-set flag must win over the config file even when
+	// -config was combined with it on the same command line.
+	*compress = "none"
+	applyConfigExplicit(&config{Compress: "zlib"}, map[string]bool{"compress": true})
+	if *compress != "none" {
+		t.Errorf("*compress = %q, want %q (explicit flag must not be overwritten)", *compress, "none")
+	}
+
+	// Unset flags still pick up the config file's value.
+	*compress = "none"
+	applyConfigExplicit(&config{Compress: "zlib"}, map[string]bool{})
+	if *compress != "zlib" {
+		t.Errorf("*compress = %q, want %q (config should apply when not explicit)", *compress, "zlib")
+	}
+}
+
+func TestApplyConfigExplicitZeroValuesLeaveFlagsAlone(t *testing.T) {
+	saved, savedPkg := *compressThreshold, *packageName
+	defer func() { *compressThreshold, *packageName = saved, savedPkg }()
+
+	*compressThreshold = 512
+	*packageName = "main"
+	applyConfigExplicit(&config{}, map[string]bool{})
+	if *compressThreshold != 512 {
+		t.Errorf("*compressThreshold = %d, want unchanged 512", *compressThreshold)
+	}
+	if *packageName != "main" {
+		t.Errorf("*packageName = %q, want unchanged %q", *packageName, "main")
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// defaultExts is the extension whitelist applied when -ext is not given.
+// It covers the file kinds a typical QML UI ships, so that things like
+// node_modules, test fixtures and editor swap files don't get packed by
+// default. qmldirBasename is always allowed regardless of extension, since
+// it has no extension of its own but must be packed for QML module resolution.
+var defaultExts = []string{
+	".qml", ".js", ".svg", ".png", ".ico", ".ttf", ".otf", ".wav", ".mp3", ".json",
+}
+
+const qmldirBasename = "qmldir"
+
+// defaultExtsLiteral renders defaultExts as Go source for embedding into the
+// []string{...} literal baked into generated qrc.go files.
+var defaultExtsLiteral = `"` + strings.Join(defaultExts, `", "`) + `"`
+
+// resourceFilter decides whether a walked file should be packed, based on
+// the -include, -exclude and -ext flags plus any .qrcignore files found
+// along the way.
+type resourceFilter struct {
+	includes []string
+	excludes []string
+	exts     map[string]bool
+
+	// ignores maps a directory to the cumulative set of .qrcignore rules
+	// that apply to it (its own rules plus everything inherited from
+	// parent directories).
+	ignores map[string][]ignoreRule
+}
+
+func newResourceFilter(include, exclude, ext string) *resourceFilter {
+	f := &resourceFilter{
+		ignores: make(map[string][]ignoreRule),
+	}
+	if include != "" {
+		f.includes = strings.Split(include, ",")
+	}
+	if exclude != "" {
+		f.excludes = strings.Split(exclude, ",")
+	}
+
+	exts := defaultExts
+	if ext != "" {
+		exts = strings.Split(ext, ",")
+	}
+	f.exts = make(map[string]bool, len(exts))
+	for _, e := range exts {
+		if !strings.HasPrefix(e, ".") {
+			e = "." + e
+		}
+		f.exts[e] = true
+	}
+	return f
+}
+
+// rulesFor returns the cumulative .qrcignore rules that apply to dir,
+// loading and caching dir's own .qrcignore (if any) on first visit.
+func (f *resourceFilter) rulesFor(dir string) []ignoreRule {
+	if rules, ok := f.ignores[dir]; ok {
+		return rules
+	}
+
+	var rules []ignoreRule
+	if parent := filepath.Dir(dir); parent != dir {
+		rules = append(rules, f.rulesFor(parent)...)
+	}
+	rules = append(rules, loadQRCIgnore(dir)...)
+
+	f.ignores[dir] = rules
+	return rules
+}
+
+// Allowed reports whether the file at slashPath should be packed.
+func (f *resourceFilter) Allowed(slashPath string) bool {
+	base := path.Base(slashPath)
+	if base != qmldirBasename && !f.exts[path.Ext(slashPath)] {
+		return false
+	}
+
+	if len(f.includes) > 0 && !matchAny(f.includes, slashPath) {
+		return false
+	}
+	if matchAny(f.excludes, slashPath) {
+		return false
+	}
+
+	if ignoredByQRCIgnore(f.rulesFor(filepath.ToSlash(path.Dir(slashPath))), slashPath) {
+		return false
+	}
+
+	return true
+}
+
+func matchAny(patterns []string, slashPath string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, slashPath); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(slashPath)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoreRule is a single line of a .qrcignore file, gitignore-style: a glob
+// pattern, optionally negated with a leading "!". baseDir is the
+// slash-normalized directory the .qrcignore file was found in, which
+// patterns containing a "/" are matched relative to (see ruleMatches).
+type ignoreRule struct {
+	baseDir string
+	pattern string
+	negate  bool
+}
+
+// loadQRCIgnore reads dir/.qrcignore, if present, returning its rules.
+func loadQRCIgnore(dir string) []ignoreRule {
+	f, err := os.Open(filepath.Join(dir, ".qrcignore"))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	baseDir := filepath.ToSlash(dir)
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := ignoreRule{baseDir: baseDir}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		rule.pattern = strings.TrimSuffix(line, "/")
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// ruleMatches reports whether rule.pattern matches slashPath. A pattern with
+// no "/" is matched against just the file's base name, so it matches a file
+// of that name at any depth; it is not matched against the directory names
+// along the path, so a bare directory name such as "node_modules" does not
+// exclude the files below it. A pattern containing a "/" is anchored to
+// rule.baseDir, so it's
+// matched against slashPath's path relative to rule.baseDir instead of the
+// full path from the walk root — otherwise a pattern like "build/*" could
+// never match something three levels down such as "assets/build/x.js",
+// since path.Match requires equal segment counts on both sides.
+func ruleMatches(rule ignoreRule, slashPath string) bool {
+	if !strings.Contains(rule.pattern, "/") {
+		matched, _ := path.Match(rule.pattern, path.Base(slashPath))
+		return matched
+	}
+	rel := slashPath
+	if rule.baseDir != "" && rule.baseDir != "." {
+		rel = strings.TrimPrefix(slashPath, rule.baseDir+"/")
+	}
+	matched, _ := path.Match(rule.pattern, rel)
+	return matched
+}
+
+// ignoredByQRCIgnore reports whether slashPath is excluded by rules, applying
+// them in order so that later rules (e.g. a negation) override earlier ones.
+func ignoredByQRCIgnore(rules []ignoreRule, slashPath string) bool {
+	ignored := false
+	for _, rule := range rules {
+		if ruleMatches(rule, slashPath) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
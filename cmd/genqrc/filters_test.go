@@ -0,0 +1,58 @@
+package main
+
+import "testing"
+
+func TestRuleMatches(t *testing.T) {
+	cases := []struct {
+		rule     ignoreRule
+		path     string
+		wantBool bool
+	}{
+		{ignoreRule{pattern: "*.bak"}, "assets/thumb.bak", true},
+		{ignoreRule{pattern: "*.bak"}, "assets/thumb.png", false},
+		{ignoreRule{baseDir: "assets", pattern: "build/*"}, "assets/build/x.js", true},
+		{ignoreRule{baseDir: "assets", pattern: "build/*"}, "assets/sub/build/x.js", false},
+		{ignoreRule{baseDir: "assets", pattern: "build/*"}, "other/build/x.js", false},
+		{ignoreRule{baseDir: ".", pattern: "build/*"}, "build/x.js", true},
+	}
+	for _, c := range cases {
+		if got := ruleMatches(c.rule, c.path); got != c.wantBool {
+			t.Errorf("ruleMatches(%+v, %q) = %v, want %v", c.rule, c.path, got, c.wantBool)
+		}
+	}
+}
+
+func TestIgnoredByQRCIgnore(t *testing.T) {
+	rules := []ignoreRule{
+		{pattern: "*.bak"},
+		{pattern: "*.log"},
+		{negate: true, pattern: "important.log"},
+	}
+	cases := map[string]bool{
+		"assets/thumb.bak":     true,
+		"assets/debug.log":     true,
+		"assets/important.log": false,
+		"assets/thumb.png":     false,
+	}
+	for path, want := range cases {
+		if got := ignoredByQRCIgnore(rules, path); got != want {
+			t.Errorf("ignoredByQRCIgnore(rules, %q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestIgnoredByQRCIgnoreLastMatchWins(t *testing.T) {
+	// A later rule must still be able to re-exclude a path an earlier
+	// negation let back in (last match wins, not first).
+	rules := []ignoreRule{
+		{pattern: "*.log"},
+		{negate: true, pattern: "*.log"},
+		{pattern: "debug.log"},
+	}
+	if !ignoredByQRCIgnore(rules, "debug.log") {
+		t.Errorf("ignoredByQRCIgnore(rules, %q) = false, want true", "debug.log")
+	}
+	if ignoredByQRCIgnore(rules, "other.log") {
+		t.Errorf("ignoredByQRCIgnore(rules, %q) = true, want false", "other.log")
+	}
+}
@@ -0,0 +1,10 @@
+package main
+
+// Generated qrc.go/bundle output imports github.com/fsnotify/fsnotify
+// directly (see the tmpl/bundlesTmpl template strings in main.go/bundle.go)
+// when QRC_WATCH support is compiled in, but genqrc itself never uses it.
+// This blank import exists solely to keep fsnotify, and the version
+// genqrc's templates were written against, pinned in go.mod/go.sum so
+// `go mod tidy` doesn't drop it and downstream builds get reproducible
+// checksums for the same version without hunting for it themselves.
+import _ "github.com/fsnotify/fsnotify"
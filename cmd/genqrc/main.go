@@ -33,14 +33,77 @@
 // This does not update the static content in the qrc.go file, though, so after
 // the changes are performed, genqrc must be run again to update the content that
 // will ship with built binaries.
+//
+// The -format flag selects the kind of manifest that is produced. The default,
+// "go", is the qrc.go file described above. The "qrc" format instead emits a
+// standard Qt .qrc XML manifest, which may be fed to Qt's own rcc tool or to
+// other Qt tooling that expects that format. When -format=qrc is combined with
+// -rcc, genqrc additionally shells out to rcc (or to a QML compiler such as
+// qmlcachegen when -quickcompiler is set) to produce a binary .rcc resource
+// pack, which can be loaded at runtime with qml.LoadRCCFile.
+//
+// The -include, -exclude and -ext flags filter which files under the given
+// subdirectories get packed, and a .qrcignore file in any walked directory
+// excludes matching paths using gitignore-style patterns.
+//
+// The -compress flag ("none", "zlib" or "zstd") compresses packed entries
+// above -compress-threshold bytes, which can cut binary size substantially
+// for large QML/SVG/JSON asset trees. Already-compressed formats such as
+// png and mp3 are skipped automatically. Compressed entries are transparently
+// decompressed by qml.ParseResources and qml.ParseResourcesString.
+//
+// Packed files are always processed in sorted path order, so qrc.go is
+// byte-identical across machines given the same inputs. A qrc.cache sidecar
+// written alongside qrc.go records each file's size, mtime, content hash and
+// already-packed bytes, letting later runs skip reading and recompressing
+// files whose size and mtime haven't changed, and skip rewriting qrc.go
+// entirely when the regenerated content is identical. The cache is
+// discarded if -compress/-compress-threshold changed since it was written.
+//
+// Setting QRC_WATCH=1 instead of QRC_REPACK=1 additionally spawns a
+// goroutine that watches SubDirs with fsnotify and, once changes settle for
+// a short debounce window, repacks and reloads the resources, so a running
+// QML app picks up edits without a restart.
+//
+// Repeating -bundle name=dir packs each dir into an independently loadable,
+// named bundle instead of a single unconditionally-loaded resource set:
+//
+//     genqrc -bundle ui=./qml -bundle assets=./images -bundle i18n=./translations
+//
+// Each bundle resolves under its own "qrc:///name/..." prefix and is
+// exposed as a func LoadNameResources() error that the application calls
+// lazily, rather than an init() that loads everything upfront. Embedded
+// bundles get their own qrc.cache sidecar (name.qrc.cache) and honor
+// QRC_REPACK/QRC_WATCH exactly like the single-bundle qrc.go output, except
+// that repacking happens inside LoadNameResources instead of an init(). The
+// -external flag takes a comma-separated list of bundle names whose packed
+// bytes should be written to a sidecar file and loaded at runtime with
+// qml.LoadResourcesFile, instead of being embedded in qrc.go — handy for
+// shipping optional or language-specific asset packs separately from the
+// main binary. QRC_REPACK/QRC_WATCH don't apply to external bundles, since
+// they already read their sidecar file fresh on every call.
+//
+// Instead of spelling everything out on the command line, genqrc can be
+// driven by a qrc.yaml manifest covering the package name, subdirs, prefix,
+// include/exclude/ext filters, compression settings, output filename and
+// bundles. When genqrc is run with no subdirectory arguments and no -bundle
+// flags, it auto-discovers ./qrc.yaml; -config path overrides which
+// manifest to load. With -format=qrc, a subdir may be written as
+// "{dir: ..., prefix: ...}" instead of a bare string to put it in its own
+// <qresource prefix="..."> block rather than the manifest-wide prefix,
+// mirroring how a real .qrc file can hold more than one qresource section.
 package main
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"sort"
+	"strings"
 	"text/template"
 
 	"github.com/i-home/qml"
@@ -77,6 +140,64 @@ setting the QRC_REPACK environment variable to 1:
 This does not update the static content in the qrc.go file, though, so after
 the changes are performed, genqrc must be run again to update the content that
 will ship with built binaries.
+
+The -format flag selects the kind of manifest that is produced. The default,
+"go", is the qrc.go file described above. The "qrc" format instead emits a
+standard Qt .qrc XML manifest, which may be fed to Qt's own rcc tool or to
+other Qt tooling that expects that format. When -format=qrc is combined with
+-rcc, genqrc additionally shells out to rcc (or to a QML compiler such as
+qmlcachegen when -quickcompiler is set) to produce a binary .rcc resource
+pack, which can be loaded at runtime with qml.LoadRCCFile.
+
+By default genqrc packs only the files under the given subdirectories whose
+extension is in a built-in whitelist of common QML asset kinds (see -ext
+below), plus any qmldir file. The -include and -exclude flags take
+comma-separated glob patterns matched against slash-normalized paths to
+narrow that down, and -ext replaces the whitelist with a comma-separated
+list of its own (the qmldir file is always allowed regardless of extension). A .qrcignore file placed in any walked directory
+excludes matching paths using gitignore-style patterns, and applies to that
+directory and everything below it.
+
+The -compress flag ("none", "zlib" or "zstd") compresses packed entries
+above -compress-threshold bytes, which can cut binary size substantially
+for large QML/SVG/JSON asset trees. Already-compressed formats such as
+png and mp3 are skipped automatically. Compressed entries are transparently
+decompressed by qml.ParseResources and qml.ParseResourcesString.
+
+Packed files are always processed in sorted path order, so qrc.go is
+byte-identical across machines given the same inputs. A qrc.cache sidecar
+written alongside qrc.go records each file's size, mtime and content hash,
+letting later runs skip rehashing unchanged files and skip rewriting qrc.go
+entirely when the regenerated content is identical.
+
+Setting QRC_WATCH=1 instead of QRC_REPACK=1 additionally spawns a goroutine
+that watches SubDirs with fsnotify and, once changes settle for a short
+debounce window, repacks and reloads the resources, so a running QML app
+picks up edits without a restart.
+
+Repeating -bundle name=dir packs each dir into an independently loadable,
+named bundle instead of a single unconditionally-loaded resource set:
+
+    genqrc -bundle ui=./qml -bundle assets=./images -bundle i18n=./translations
+
+Each bundle resolves under its own "qrc:///name/..." prefix and is exposed
+as a func LoadNameResources() error that the application calls lazily,
+rather than an init() that loads everything upfront. Embedded bundles get
+their own qrc.cache sidecar and honor QRC_REPACK/QRC_WATCH exactly like the
+single-bundle qrc.go output. The -external flag takes a comma-separated
+list of bundle names whose packed bytes should be written to a sidecar
+file and loaded at runtime with qml.LoadResourcesFile, instead of being
+embedded in qrc.go; QRC_REPACK/QRC_WATCH don't apply to those, since they
+already read their sidecar file fresh on every call.
+
+Instead of spelling everything out on the command line, genqrc can be driven
+by a qrc.yaml manifest covering the package name, subdirs, prefix,
+include/exclude/ext filters, compression settings, output filename and
+bundles. When genqrc is run with no subdirectory arguments and no -bundle
+flags, it auto-discovers ./qrc.yaml; -config path overrides which manifest
+to load. With -format=qrc, a subdir may be written as
+"{dir: ..., prefix: ...}" instead of a bare string to put it in its own
+<qresource prefix="..."> block rather than the manifest-wide prefix.
 `
 
 // XXX: The documentation is duplicated here and in the the package comment
@@ -84,6 +205,23 @@ will ship with built binaries.
 
 var packageName = flag.String("package", "main", "package name that qrc.go will be under (not needed for go generate)")
 
+var (
+	format         = flag.String("format", "go", `manifest format to emit: "go" for qrc.go or "qrc" for a Qt .qrc XML manifest`)
+	outputPath     = flag.String("o", "", "output file path (defaults to qrc.go or resources.qrc depending on -format)")
+	prefix         = flag.String("prefix", "/", "qresource prefix recorded in the .qrc manifest (-format=qrc only)")
+	rccMode        = flag.Bool("rcc", false, "also invoke Qt's rcc to produce a binary .rcc resource pack (-format=qrc only)")
+	quickcompiler  = flag.Bool("quickcompiler", false, "use qmlcachegen instead of rcc to ahead-of-time compile QML into the .rcc pack (implies -rcc)")
+	rccBin         = flag.String("rcc-bin", "rcc", "path to the rcc binary to invoke when -rcc is set")
+	qmlcachegenBin = flag.String("qmlcachegen-bin", "qmlcachegen", "path to the qmlcachegen binary to invoke when -quickcompiler is set")
+
+	include = flag.String("include", "", "comma-separated glob patterns; when set, only matching slash-normalized paths are packed")
+	exclude = flag.String("exclude", "", "comma-separated glob patterns matched against slash-normalized paths to exclude from packing")
+	ext     = flag.String("ext", "", "comma-separated extension whitelist (default: "+strings.Join(defaultExts, ",")+", plus qmldir)")
+
+	compress          = flag.String("compress", "none", `compression codec for packed entries: "none", "zlib" or "zstd"`)
+	compressThreshold = flag.Int("compress-threshold", 256, "skip compression for files smaller than this many bytes")
+)
+
 func main() {
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "%s", doc)
@@ -97,45 +235,144 @@ func main() {
 }
 
 func run() error {
+	if path := resolveConfigPath(); path != "" {
+		cfg, err := loadConfig(path)
+		if err != nil {
+			return err
+		}
+		return runConfig(cfg)
+	}
+
+	if specs := bundleList.specs(); len(specs) > 0 {
+		return runBundlesFormat(specs)
+	}
+
 	subdirs := flag.Args()
 	if len(subdirs) == 0 {
 		return fmt.Errorf("must provide at least one subdirectory path")
 	}
 
-	var rp qml.ResourcesPacker
+	switch *format {
+	case "go":
+		return runGoFormat(subdirs)
+	case "qrc":
+		return runQRCFormat(subdirs)
+	default:
+		return fmt.Errorf("unknown -format %q: must be \"go\" or \"qrc\"", *format)
+	}
+}
 
-	for _, subdir := range flag.Args() {
-		err := filepath.Walk(subdir, func(path string, info os.FileInfo, err error) error {
+// walkResourceFiles walks subdirs in order and invokes fn for every regular
+// file found that passes filter, with its path slash-normalized for use as
+// a resource name. It does not read the file itself, leaving that decision
+// (and the cost of it) to fn, which may be able to skip reading a file
+// whose cached size/mtime still match (see resourceCache.unchanged).
+func walkResourceFiles(subdirs []string, filter *resourceFilter, fn func(slashPath, fsPath string, info os.FileInfo) error) error {
+	for _, subdir := range subdirs {
+		err := filepath.Walk(subdir, func(fsPath string, info os.FileInfo, err error) error {
 			if err != nil {
 				return err
 			}
 			if info.IsDir() {
 				return nil
 			}
-			data, err := ioutil.ReadFile(path)
-			if err != nil {
-				return err
+			slashPath := filepath.ToSlash(fsPath)
+			if !filter.Allowed(slashPath) {
+				return nil
 			}
-			rp.Add(filepath.ToSlash(path), data)
-			return nil
+			return fn(slashPath, fsPath, info)
 		})
 		if err != nil {
 			return err
 		}
 	}
+	return nil
+}
 
-	resdata := rp.Pack().Bytes()
+// resourceEntry is a single file collected for packing, sortable by path so
+// that pack output (and thus the generated qrc.go) is deterministic.
+type resourceEntry struct {
+	SlashPath string
+	FSPath    string
+	Size      int64
+	ModTime   int64
+}
+
+type byPath []resourceEntry
 
-	f, err := os.Create("qrc.go")
+func (e byPath) Len() int           { return len(e) }
+func (e byPath) Less(i, j int) bool { return e[i].SlashPath < e[j].SlashPath }
+func (e byPath) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
+
+func runGoFormat(subdirs []string) error {
+	algo, err := resolveCompression(*compress)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
+
+	output := *outputPath
+	if output == "" {
+		output = "qrc.go"
+	}
+	cacheDir := filepath.Dir(output)
+
+	signature := cacheSignature(*compress, *compressThreshold)
+	oldCache := loadResourceCache(cacheDir, cacheFile, signature)
+
+	var entries []resourceEntry
+	filter := newResourceFilter(*include, *exclude, *ext)
+	err = walkResourceFiles(subdirs, filter, func(slashPath, fsPath string, info os.FileInfo) error {
+		entries = append(entries, resourceEntry{
+			SlashPath: slashPath,
+			FSPath:    fsPath,
+			Size:      info.Size(),
+			ModTime:   info.ModTime().UnixNano(),
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Sort(byPath(entries))
+
+	var rp qml.ResourcesPacker
+	rp.SetCompression(algo, *compressThreshold)
+
+	newCache := resourceCache{Signature: signature, Entries: make(map[string]cacheEntry, len(entries))}
+	for _, e := range entries {
+		if prev, ok := oldCache.unchanged(e.SlashPath, e.Size, e.ModTime); ok {
+			rp.AddPacked(e.SlashPath, prev.Compress, prev.OrigSize, prev.Packed)
+			newCache.Entries[e.SlashPath] = prev
+			continue
+		}
+
+		data, err := ioutil.ReadFile(e.FSPath)
+		if err != nil {
+			return err
+		}
+		rp.Add(e.SlashPath, data)
+		packedCompress, origSize, packed := rp.LastPacked()
+		newCache.Entries[e.SlashPath] = cacheEntry{
+			Size:     e.Size,
+			ModTime:  e.ModTime,
+			Hash:     hashContent(data),
+			Compress: packedCompress,
+			OrigSize: origSize,
+			Packed:   packed,
+		}
+	}
+
+	resdata := rp.Pack().Bytes()
 
 	data := templateData{
-		PackageName:   *packageName,
-		SubDirs:       subdirs,
-		ResourcesData: resdata,
+		PackageName:       *packageName,
+		SubDirs:           subdirs,
+		ResourcesData:     resdata,
+		Include:           *include,
+		Exclude:           *exclude,
+		Ext:               *ext,
+		Compress:          *compress,
+		CompressThreshold: *compressThreshold,
 	}
 
 	// $GOPACKAGE is set automatically by go generate.
@@ -143,13 +380,117 @@ func run() error {
 		data.PackageName = pkgname
 	}
 
-	return tmpl.Execute(f, data)
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+
+	if old, err := ioutil.ReadFile(output); err != nil || !bytes.Equal(old, buf.Bytes()) {
+		if err := ioutil.WriteFile(output, buf.Bytes(), 0644); err != nil {
+			return err
+		}
+	}
+
+	return saveResourceCache(cacheDir, cacheFile, newCache)
+}
+
+// runQRCFormat emits a standard Qt .qrc XML manifest listing the files found
+// under subdirs in a single qresource block under -prefix, and optionally
+// invokes rcc or qmlcachegen to compile that manifest into a binary .rcc
+// resource pack.
+func runQRCFormat(subdirs []string) error {
+	var files []string
+	filter := newResourceFilter(*include, *exclude, *ext)
+	err := walkResourceFiles(subdirs, filter, func(slashPath, fsPath string, info os.FileInfo) error {
+		files = append(files, slashPath)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	return writeManifestAndCompile([]qrcGroup{{Prefix: *prefix, Files: files}})
+}
+
+// runQRCFormatGrouped is runQRCFormat for a qrc.yaml manifest whose subdirs
+// may each carry their own prefix: every subdir gets its own qresource
+// block instead of all of them sharing a single global -prefix.
+func runQRCFormatGrouped(subdirs []configSubdir) error {
+	filter := newResourceFilter(*include, *exclude, *ext)
+
+	var groups []qrcGroup
+	for _, s := range subdirs {
+		groupPrefix := s.Prefix
+		if groupPrefix == "" {
+			groupPrefix = *prefix
+		}
+
+		var files []string
+		err := walkResourceFiles([]string{s.Dir}, filter, func(slashPath, fsPath string, info os.FileInfo) error {
+			files = append(files, slashPath)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		sort.Strings(files)
+
+		groups = append(groups, qrcGroup{Prefix: groupPrefix, Files: files})
+	}
+
+	return writeManifestAndCompile(groups)
+}
+
+// writeManifestAndCompile writes groups to the .qrc manifest and, if -rcc or
+// -quickcompiler is set, compiles it into a binary .rcc resource pack.
+func writeManifestAndCompile(groups []qrcGroup) error {
+	qrcPath := *outputPath
+	if qrcPath == "" {
+		qrcPath = "resources.qrc"
+	}
+
+	if err := writeQRCManifest(qrcPath, groups); err != nil {
+		return err
+	}
+
+	if *rccMode || *quickcompiler {
+		rccPath := strings.TrimSuffix(qrcPath, filepath.Ext(qrcPath)) + ".rcc"
+		if err := compileRCC(qrcPath, rccPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// compileRCC shells out to rcc, or to qmlcachegen when -quickcompiler is set,
+// to compile qrcPath into the binary resource pack rccPath. The result can be
+// loaded at runtime with qml.LoadRCCFile.
+func compileRCC(qrcPath, rccPath string) error {
+	var cmd *exec.Cmd
+	if *quickcompiler {
+		cmd = exec.Command(*qmlcachegenBin, "--resource-name", rccPath, qrcPath)
+	} else {
+		cmd = exec.Command(*rccBin, "-binary", "-o", rccPath, qrcPath)
+	}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("running %s: %v", cmd.Path, err)
+	}
+	return nil
 }
 
 type templateData struct {
-	PackageName   string
-	SubDirs       []string
-	ResourcesData []byte
+	PackageName       string
+	SubDirs           []string
+	ResourcesData     []byte
+	Include           string
+	Exclude           string
+	Ext               string
+	Compress          string
+	CompressThreshold int
 }
 
 func buildTemplate(name, content string) *template.Template {
@@ -161,17 +502,168 @@ var tmpl = buildTemplate("qrc.go", `package {{.PackageName}}
 // This file is automatically generated by github.com/i-home/qml/cmd/genqrc
 
 import (
+	"bufio"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/i-home/qml"
 )
 
+// qrcWatchDebounce coalesces bursts of fsnotify events (e.g. an editor
+// writing a file via rename-and-replace fires several in a row) into a
+// single repack, so a large tree doesn't get repacked once per raw event.
+const qrcWatchDebounce = 200 * time.Millisecond
+
+// qrcRepackInclude, qrcRepackExclude and qrcRepackExt mirror the -include,
+// -exclude and -ext flags genqrc was run with, so that QRC_REPACK=1 filters
+// files the same way the bundled data was filtered at generation time.
+var (
+	qrcRepackInclude = {{printf "%q" .Include}}
+	qrcRepackExclude = {{printf "%q" .Exclude}}
+	qrcRepackExt     = {{printf "%q" .Ext}}
+)
+
+var qrcRepackDefaultExt = []string{` + defaultExtsLiteral + `}
+
+const qrcRepackQmldirBasename = "qmldir"
+
+func qrcRepackSplit(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+func qrcRepackMatchAny(patterns []string, slashPath string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, slashPath); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(slashPath)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// qrcRepackRule mirrors filters.go's ignoreRule: baseDir is the
+// slash-normalized directory the .qrcignore line came from, which patterns
+// containing a "/" are matched relative to.
+type qrcRepackRule struct {
+	baseDir string
+	pattern string
+	negate  bool
+}
+
+func qrcRepackLoadIgnore(dir string) []qrcRepackRule {
+	f, err := os.Open(filepath.Join(dir, ".qrcignore"))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	baseDir := filepath.ToSlash(dir)
+	var rules []qrcRepackRule
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := qrcRepackRule{baseDir: baseDir}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		rule.pattern = strings.TrimSuffix(line, "/")
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// qrcRepackRuleMatches mirrors filters.go's ruleMatches.
+func qrcRepackRuleMatches(rule qrcRepackRule, slashPath string) bool {
+	if !strings.Contains(rule.pattern, "/") {
+		matched, _ := path.Match(rule.pattern, path.Base(slashPath))
+		return matched
+	}
+	rel := slashPath
+	if rule.baseDir != "" && rule.baseDir != "." {
+		rel = strings.TrimPrefix(slashPath, rule.baseDir+"/")
+	}
+	matched, _ := path.Match(rule.pattern, rel)
+	return matched
+}
+
+// qrcRepackAllowed replicates the include/exclude/ext and .qrcignore
+// filtering genqrc applied when qrc.go was generated. The .qrcignore rules
+// accumulate with last-match-wins semantics, same as build-time
+// ignoredByQRCIgnore, so a child directory's negated rule can override one
+// inherited from a parent .qrcignore.
+func qrcRepackAllowed(ignoreCache map[string][]qrcRepackRule, slashPath string) bool {
+	exts := qrcRepackDefaultExt
+	if qrcRepackExt != "" {
+		exts = qrcRepackSplit(qrcRepackExt)
+	}
+	base := path.Base(slashPath)
+	if base != qrcRepackQmldirBasename {
+		ok := false
+		ext := path.Ext(slashPath)
+		for _, e := range exts {
+			if !strings.HasPrefix(e, ".") {
+				e = "." + e
+			}
+			if e == ext {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+
+	includes := qrcRepackSplit(qrcRepackInclude)
+	if len(includes) > 0 && !qrcRepackMatchAny(includes, slashPath) {
+		return false
+	}
+	if qrcRepackMatchAny(qrcRepackSplit(qrcRepackExclude), slashPath) {
+		return false
+	}
+
+	ignored := false
+	for _, rule := range qrcRepackRulesFor(ignoreCache, path.Dir(slashPath)) {
+		if qrcRepackRuleMatches(rule, slashPath) {
+			ignored = !rule.negate
+		}
+	}
+	return !ignored
+}
+
+// qrcRepackRulesFor returns the cumulative .qrcignore rules that apply to
+// dir: its own rules plus everything inherited from parent directories.
+func qrcRepackRulesFor(ignoreCache map[string][]qrcRepackRule, dir string) []qrcRepackRule {
+	if rules, ok := ignoreCache[dir]; ok {
+		return rules
+	}
+	rules := qrcRepackLoadIgnore(filepath.FromSlash(dir))
+	if parent := path.Dir(dir); parent != dir {
+		rules = append(qrcRepackRulesFor(ignoreCache, parent), rules...)
+	}
+	ignoreCache[dir] = rules
+	return rules
+}
+
 func init() {
 	var r *qml.Resources
 	var err error
-	if os.Getenv("QRC_REPACK") == "1" {
+	if os.Getenv("QRC_REPACK") == "1" || os.Getenv("QRC_WATCH") == "1" {
 		err = qrcRepackResources()
 		if err != nil {
 			panic("cannot repack qrc resources: " + err.Error())
@@ -183,31 +675,136 @@ func init() {
 	if err != nil {
 		panic("cannot parse bundled resources data: " + err.Error())
 	}
-	qml.LoadResources(r)
+	qml.LoadResources("", r)
+
+	if os.Getenv("QRC_WATCH") == "1" {
+		go qrcWatchResources()
+	}
+}
+
+// qrcWatchResources watches the original SubDirs for changes and, once
+// events stop arriving for qrcWatchDebounce, repacks them into a fresh
+// qml.Resources and reloads it so a running QML app picks up edits without
+// a restart. Debouncing coalesces a burst of events (e.g. an editor's
+// rename-and-replace save, or a git checkout touching many files at once)
+// into a single repack instead of one per raw fsnotify event. Errors are
+// logged to stderr rather than panicking, since a transient watch failure
+// shouldn't take down an otherwise-running application.
+func qrcWatchResources() {
+	subdirs := {{printf "%#v" .SubDirs}}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "qrc watch: cannot start fsnotify watcher:", err)
+		return
+	}
+	defer watcher.Close()
+
+	addWatches := func() {
+		for _, subdir := range subdirs {
+			filepath.Walk(subdir, func(path string, info os.FileInfo, err error) error {
+				if err == nil && info.IsDir() {
+					watcher.Add(path)
+				}
+				return nil
+			})
+		}
+	}
+	addWatches()
+
+	debounce := time.NewTimer(qrcWatchDebounce)
+	if !debounce.Stop() {
+		<-debounce.C
+	}
+	pending := false
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Create|fsnotify.Write|fsnotify.Remove|fsnotify.Rename) == 0 {
+				continue
+			}
+			// A newly created directory needs its own watch.
+			if event.Op&fsnotify.Create != 0 {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					watcher.Add(event.Name)
+				}
+			}
+			if pending {
+				if !debounce.Stop() {
+					<-debounce.C
+				}
+			}
+			pending = true
+			debounce.Reset(qrcWatchDebounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			fmt.Fprintln(os.Stderr, "qrc watch: fsnotify error:", err)
+		case <-debounce.C:
+			pending = false
+			if err := qrcRepackResources(); err != nil {
+				fmt.Fprintln(os.Stderr, "qrc watch: repack failed:", err)
+				continue
+			}
+			r, err := qml.ParseResources(qrcResourcesRepacked)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "qrc watch: cannot parse repacked resources:", err)
+				continue
+			}
+			qml.LoadResources("", r)
+		}
+	}
 }
 
 func qrcRepackResources() error {
 	subdirs := {{printf "%#v" .SubDirs}}
 	var rp qml.ResourcesPacker
+	switch {{printf "%q" .Compress}} {
+	case "zlib":
+		rp.SetCompression(qml.CompressZlib, {{.CompressThreshold}})
+	case "zstd":
+		rp.SetCompression(qml.CompressZstd, {{.CompressThreshold}})
+	default:
+		rp.SetCompression(qml.CompressNone, {{.CompressThreshold}})
+	}
+	ignoreCache := make(map[string][]qrcRepackRule)
+	type qrcRepackEntry struct {
+		SlashPath string
+		Data      []byte
+	}
+	var entries []qrcRepackEntry
 	for _, subdir := range subdirs {
-		err := filepath.Walk(subdir, func(path string, info os.FileInfo, err error) error {
+		err := filepath.Walk(subdir, func(fpath string, info os.FileInfo, err error) error {
 			if err != nil {
 				return err
 			}
 			if info.IsDir() {
 				return nil
 			}
-			data, err := ioutil.ReadFile(path)
+			slashPath := filepath.ToSlash(fpath)
+			if !qrcRepackAllowed(ignoreCache, slashPath) {
+				return nil
+			}
+			data, err := ioutil.ReadFile(fpath)
 			if err != nil {
 				return err
 			}
-			rp.Add(filepath.ToSlash(path), data)
+			entries = append(entries, qrcRepackEntry{slashPath, data})
 			return nil
 		})
 		if err != nil {
 			return err
 		}
 	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].SlashPath < entries[j].SlashPath })
+	for _, e := range entries {
+		rp.Add(e.SlashPath, e.Data)
+	}
 	qrcResourcesRepacked = rp.Pack().Bytes()
 	return nil
 }
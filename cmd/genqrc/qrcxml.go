@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/xml"
+	"os"
+)
+
+// qrcDocument mirrors the subset of Qt's .qrc manifest format that genqrc
+// produces: one or more qresource blocks, each with its own prefix, listing
+// the files packed under it.
+type qrcDocument struct {
+	XMLName xml.Name      `xml:"RCC"`
+	Version string        `xml:"version,attr"`
+	Res     []qrcResource `xml:"qresource"`
+}
+
+type qrcResource struct {
+	Prefix string   `xml:"prefix,attr"`
+	Files  []string `xml:"file"`
+}
+
+const qrcDoctype = "<!DOCTYPE RCC>\n"
+
+// qrcGroup is one <qresource prefix="..."> block: every file in Files is
+// written under Prefix.
+type qrcGroup struct {
+	Prefix string
+	Files  []string
+}
+
+// writeQRCManifest writes a Qt .qrc XML manifest with one qresource block
+// per group to path.
+func writeQRCManifest(path string, groups []qrcGroup) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(xml.Header); err != nil {
+		return err
+	}
+	if _, err := f.WriteString(qrcDoctype); err != nil {
+		return err
+	}
+
+	doc := qrcDocument{Version: "1.0"}
+	for _, g := range groups {
+		doc.Res = append(doc.Res, qrcResource{Prefix: g.Prefix, Files: g.Files})
+	}
+
+	enc := xml.NewEncoder(f)
+	enc.Indent("", "    ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err = f.WriteString("\n")
+	return err
+}
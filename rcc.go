@@ -0,0 +1,22 @@
+package qml
+
+import "fmt"
+
+// registerRCCFile is set by the cgo bridge (built alongside the Qt engine
+// bindings) to QResource::registerResource. It is nil in builds that don't
+// link that bridge, e.g. the genqrc command itself, which only needs the
+// pure-Go packing side of this package.
+var registerRCCFile func(path string, prefixes []string) error
+
+// LoadRCCFile registers the binary .rcc resource pack at path with Qt's
+// resource system under prefixes (each "/"-rooted, matching the -prefix a
+// "-format=qrc -rcc" genqrc invocation wrote into the .qrc manifest it was
+// compiled from), wrapping QResource::registerResource. Resources loaded
+// this way are resolved by the QML engine directly, without going through
+// Resources/LoadResources.
+func LoadRCCFile(path string, prefixes []string) error {
+	if registerRCCFile == nil {
+		return fmt.Errorf("qml: LoadRCCFile requires the cgo Qt engine bridge, not linked into this binary")
+	}
+	return registerRCCFile(path, prefixes)
+}
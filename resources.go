@@ -0,0 +1,283 @@
+// Package qml provides Go bindings to Qt's QML engine, along with the
+// resource-packing primitives used by github.com/i-home/qml/cmd/genqrc to
+// embed QML/asset trees into a Go binary.
+package qml
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+)
+
+// Compression identifies the codec used to compress a single packed
+// resource entry. ResourcesPacker records it per entry, so a single
+// Resources set may freely mix compressed and uncompressed files.
+type Compression int
+
+const (
+	CompressNone Compression = iota
+	CompressZlib
+	CompressZstd
+)
+
+// packedEntry is one file packed by a ResourcesPacker.
+type packedEntry struct {
+	path     string
+	compress Compression
+	origSize int64
+	data     []byte // data as it should be written, i.e. already compressed
+}
+
+// ResourcesPacker accumulates files into a single packed blob, suitable for
+// embedding in a Go source file or writing to a sidecar loaded at runtime
+// with LoadResourcesFile. Its zero value is ready to use.
+type ResourcesPacker struct {
+	entries   []packedEntry
+	compress  Compression
+	threshold int
+}
+
+// SetCompression selects the codec applied to entries added after this
+// call, and the minimum size in bytes an entry must reach before it is
+// compressed at all. Files already stored in a compressed format (e.g.
+// png, jpg, mp3, ogg, woff2) are left uncompressed regardless.
+func (rp *ResourcesPacker) SetCompression(algo Compression, threshold int) {
+	rp.compress = algo
+	rp.threshold = threshold
+}
+
+// alreadyCompressedExt lists file extensions whose content is already
+// compressed, so re-compressing them would only spend CPU for no gain.
+var alreadyCompressedExt = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".webp": true,
+	".mp3": true, ".ogg": true, ".mp4": true, ".woff": true, ".woff2": true,
+	".zip": true, ".gz": true,
+}
+
+// Add packs data under path, compressing it according to the most recent
+// SetCompression call.
+func (rp *ResourcesPacker) Add(path string, data []byte) {
+	algo := rp.compress
+	if algo != CompressNone && (len(data) < rp.threshold || alreadyCompressedExt[extOf(path)]) {
+		algo = CompressNone
+	}
+
+	packed := data
+	if algo != CompressNone {
+		compressed, err := compress(algo, data)
+		if err != nil || len(compressed) >= len(data) {
+			algo = CompressNone
+		} else {
+			packed = compressed
+		}
+	}
+
+	rp.entries = append(rp.entries, packedEntry{
+		path:     path,
+		compress: algo,
+		origSize: int64(len(data)),
+		data:     packed,
+	})
+}
+
+// LastPacked returns the compression algorithm, original size and final
+// (possibly compressed) bytes of the entry most recently added with Add,
+// so a caller that caches packed data (e.g. genqrc's qrc.cache) can record
+// exactly what was produced without redoing the compression itself.
+func (rp *ResourcesPacker) LastPacked() (Compression, int64, []byte) {
+	last := rp.entries[len(rp.entries)-1]
+	return last.compress, last.origSize, last.data
+}
+
+// AddPacked adds an entry whose bytes are already in their final, on-disk
+// form (data may already be compressed under compress), skipping the read
+// and compression steps Add would otherwise perform. genqrc uses this to
+// reuse qrc.cache entries for files that haven't changed since the last run.
+func (rp *ResourcesPacker) AddPacked(path string, compress Compression, origSize int64, data []byte) {
+	rp.entries = append(rp.entries, packedEntry{
+		path:     path,
+		compress: compress,
+		origSize: origSize,
+		data:     data,
+	})
+}
+
+// Pack finalizes the packer into an immutable Resources set.
+func (rp *ResourcesPacker) Pack() *Resources {
+	entries := make([]packedEntry, len(rp.entries))
+	copy(entries, rp.entries)
+	return &Resources{entries: entries}
+}
+
+func extOf(path string) string {
+	for i := len(path) - 1; i >= 0 && path[i] != '/'; i-- {
+		if path[i] == '.' {
+			return path[i:]
+		}
+	}
+	return ""
+}
+
+// Resources is an immutable set of packed files, as produced by
+// ResourcesPacker.Pack or parsed back with ParseResources.
+type Resources struct {
+	entries []packedEntry
+}
+
+// Bytes serializes r to the binary format understood by ParseResources.
+func (r *Resources) Bytes() []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, uint32(len(r.entries)))
+	for _, e := range r.entries {
+		path := []byte(e.path)
+		binary.Write(&buf, binary.BigEndian, uint32(len(path)))
+		buf.Write(path)
+		buf.WriteByte(byte(e.compress))
+		binary.Write(&buf, binary.BigEndian, uint64(e.origSize))
+		binary.Write(&buf, binary.BigEndian, uint64(len(e.data)))
+		buf.Write(e.data)
+	}
+	return buf.Bytes()
+}
+
+// Open returns the decompressed content of the packed file at path.
+func (r *Resources) Open(path string) ([]byte, error) {
+	for _, e := range r.entries {
+		if e.path == path {
+			return decompress(e.compress, e.data)
+		}
+	}
+	return nil, fmt.Errorf("qml: resource not found: %s", path)
+}
+
+// Paths returns the slash-separated paths of every file in r.
+func (r *Resources) Paths() []string {
+	paths := make([]string, len(r.entries))
+	for i, e := range r.entries {
+		paths[i] = e.path
+	}
+	return paths
+}
+
+// ParseResources decodes the binary format written by Resources.Bytes,
+// transparently decompressing entries as they're read back out via Open.
+func ParseResources(data []byte) (*Resources, error) {
+	buf := bytes.NewReader(data)
+	var count uint32
+	if err := binary.Read(buf, binary.BigEndian, &count); err != nil {
+		return nil, fmt.Errorf("qml: invalid resources data: %v", err)
+	}
+	entries := make([]packedEntry, 0, count)
+	for i := uint32(0); i < count; i++ {
+		var pathLen uint32
+		if err := binary.Read(buf, binary.BigEndian, &pathLen); err != nil {
+			return nil, fmt.Errorf("qml: invalid resources data: %v", err)
+		}
+		path := make([]byte, pathLen)
+		if _, err := io.ReadFull(buf, path); err != nil {
+			return nil, fmt.Errorf("qml: invalid resources data: %v", err)
+		}
+		algo, err := buf.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("qml: invalid resources data: %v", err)
+		}
+		var origSize, dataLen uint64
+		if err := binary.Read(buf, binary.BigEndian, &origSize); err != nil {
+			return nil, fmt.Errorf("qml: invalid resources data: %v", err)
+		}
+		if err := binary.Read(buf, binary.BigEndian, &dataLen); err != nil {
+			return nil, fmt.Errorf("qml: invalid resources data: %v", err)
+		}
+		entryData := make([]byte, dataLen)
+		if _, err := io.ReadFull(buf, entryData); err != nil {
+			return nil, fmt.Errorf("qml: invalid resources data: %v", err)
+		}
+		entries = append(entries, packedEntry{
+			path:     string(path),
+			compress: Compression(algo),
+			origSize: int64(origSize),
+			data:     entryData,
+		})
+	}
+	return &Resources{entries: entries}, nil
+}
+
+// ParseResourcesString is ParseResources for the string form genqrc embeds
+// a packed blob as (via a Go string literal in the generated qrc.go).
+func ParseResourcesString(data string) (*Resources, error) {
+	return ParseResources([]byte(data))
+}
+
+func compress(algo Compression, data []byte) ([]byte, error) {
+	switch algo {
+	case CompressZlib:
+		var buf bytes.Buffer
+		w := zlib.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case CompressZstd:
+		return compressZstd(data)
+	default:
+		return data, nil
+	}
+}
+
+func decompress(algo Compression, data []byte) ([]byte, error) {
+	switch algo {
+	case CompressNone:
+		return data, nil
+	case CompressZlib:
+		r, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return ioutil.ReadAll(r)
+	case CompressZstd:
+		return decompressZstd(data)
+	default:
+		return nil, fmt.Errorf("qml: unknown compression algorithm %d", algo)
+	}
+}
+
+// resourcesMu guards the process-wide set of loaded resource trees.
+var (
+	resourcesMu sync.Mutex
+	loaded      = map[string]*Resources{}
+)
+
+// LoadResources registers r under key so that engines created afterwards
+// can resolve "qrc:///..." URLs against it. key identifies the logical
+// resource set — a bundle name, or "" for the single-bundle qrc.go output
+// — and loading the same key again (e.g. after a QRC_REPACK/QRC_WATCH
+// repack) replaces the previous set registered under it instead of
+// accumulating alongside it.
+func LoadResources(key string, r *Resources) {
+	resourcesMu.Lock()
+	defer resourcesMu.Unlock()
+	loaded[key] = r
+}
+
+// LoadResourcesFile reads path (as written by an external -bundle sidecar)
+// and loads it with LoadResources under key.
+func LoadResourcesFile(key, path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	r, err := ParseResources(data)
+	if err != nil {
+		return fmt.Errorf("qml: %s: %v", path, err)
+	}
+	LoadResources(key, r)
+	return nil
+}
@@ -0,0 +1,26 @@
+package qml
+
+import (
+	"github.com/klauspost/compress/zstd"
+)
+
+// compressZstd and decompressZstd isolate the zstd codec in its own file
+// since, unlike zlib, it isn't in the standard library.
+
+func compressZstd(data []byte) ([]byte, error) {
+	w, err := zstd.NewWriter(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer w.Close()
+	return w.EncodeAll(data, nil), nil
+}
+
+func decompressZstd(data []byte) ([]byte, error) {
+	r, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return r.DecodeAll(data, nil)
+}